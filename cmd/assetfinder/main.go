@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/tomnomnom/assetfinder/pkg/assetfind"
+)
+
+func main() {
+	configPath := flag.String("config", "", "path to a YAML or JSON config file with source credentials (.yml/.yaml is parsed as YAML)")
+	sourcesFlag := flag.String("sources", "", "comma-separated list of sources to use (default: all)")
+	excludeFlag := flag.String("exclude-sources", "", "comma-separated list of sources to skip")
+	listSources := flag.Bool("list-sources", false, "print every known source name and exit")
+	outPath := flag.String("o", "", "write output to this file instead of stdout")
+	asJSON := flag.Bool("oJ", false, "write a JSON array of {host,sources,first_seen} instead of plain text")
+	asJSONL := flag.Bool("oL", false, "write newline-delimited JSON instead of plain text")
+	asCSV := flag.Bool("oC", false, "write CSV (host,sources,first_seen,stale) instead of plain text")
+	recursive := flag.Bool("recursive", false, "re-feed discovered subdomains back into the sources")
+	depth := flag.Int("depth", 2, "how many levels deep -recursive goes")
+	resolversPath := flag.String("resolvers", "", "file of resolver IPs to use instead of the system resolver")
+	active := flag.Bool("active", false, "only emit subdomains that resolve and aren't DNS wildcard matches")
+	timeout := flag.Duration("timeout", 15*time.Second, "per-request HTTP timeout")
+	maxRetries := flag.Int("max-retries", 3, "max retries for 429/5xx responses, with exponential backoff")
+	cacheTTL := flag.Duration("cache-ttl", 24*time.Hour, "how long a cached response stays fresh")
+	noCache := flag.Bool("no-cache", false, "don't read or write the on-disk response cache")
+	flag.Parse()
+
+	if *listSources {
+		for _, name := range assetfind.SourceNames() {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	domainArg := flag.Arg(0)
+	if domainArg == "" {
+		fmt.Println("no domain specified")
+		return
+	}
+
+	domains, err := readDomains(domainArg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: %s\n", err)
+		return
+	}
+
+	mode, err := outputModeFromFlags(*asJSON, *asJSONL, *asCSV)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: %s\n", err)
+		return
+	}
+
+	w := os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "err: failed to open %s: %s\n", *outPath, err)
+			return
+		}
+		defer f.Close()
+		w = f
+	}
+
+	cfg, err := assetfind.LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: failed to load config: %s\n", err)
+		return
+	}
+
+	sources, err := selectSources(*sourcesFlag, *excludeFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: %s\n", err)
+		return
+	}
+
+	resolver, err := assetfind.NewResolver(*resolversPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: %s\n", err)
+		return
+	}
+
+	cache, err := assetfind.NewResponseCache(*cacheTTL, !*noCache)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "err: failed to open response cache: %s\n", err)
+		return
+	}
+
+	// cancelling on SIGINT lets in-flight requests unwind and the output
+	// channel close cleanly, instead of dropping whatever's been found so far.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := assetfind.Options{
+		Sources:    sources,
+		Config:     cfg,
+		Recursive:  *recursive,
+		Depth:      *depth,
+		Active:     *active,
+		Resolver:   resolver,
+		Timeout:    *timeout,
+		MaxRetries: *maxRetries,
+		Cache:      cache,
+	}
+
+	results := make(chan assetfind.Result)
+	go func() {
+		defer close(results)
+		for _, d := range domains {
+			out, err := assetfind.Enumerate(ctx, d, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "err: %s\n", err)
+				continue
+			}
+			for r := range out {
+				results <- r
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	collectAndWrite(w, mode, results)
+}
+
+// readDomains returns the domain(s) to enumerate. A bare "-" reads
+// newline-delimited domains from stdin, so a large target list can be
+// piped straight in instead of invoked once per domain.
+func readDomains(arg string) ([]string, error) {
+	if arg != "-" {
+		return []string{arg}, nil
+	}
+
+	var domains []string
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		if d := strings.TrimSpace(sc.Text()); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains, sc.Err()
+}
+
+// collectAndWrite prints as results arrive in plain-text mode, same as
+// before; the structured modes need every result in hand before they can
+// write the sources array, so they buffer until out is closed. assetfind
+// already merges sources per host, so there's nothing left to de-dupe
+// here beyond tracking the order hosts were first seen.
+func collectAndWrite(w io.Writer, mode outputMode, out <-chan assetfind.Result) {
+	byHost := make(map[string]assetfind.Result)
+	var order []string
+	printed := make(map[string]bool)
+
+	for r := range out {
+		if _, ok := byHost[r.Host]; !ok {
+			order = append(order, r.Host)
+		}
+		byHost[r.Host] = r
+
+		if mode == outPlain && !printed[r.Host] {
+			fmt.Fprintln(w, r.Host)
+			printed[r.Host] = true
+		}
+	}
+
+	if mode == outPlain {
+		return
+	}
+
+	results := make([]assetfind.Result, 0, len(order))
+	for _, host := range order {
+		results = append(results, byHost[host])
+	}
+
+	if err := writeResults(w, mode, results); err != nil {
+		fmt.Fprintf(os.Stderr, "err: failed to write output: %s\n", err)
+	}
+}
+
+// outputModeFromFlags maps the -oJ/-oL/-oC flags to an outputMode. They're
+// mutually exclusive; none of them set means the default plain text.
+func outputModeFromFlags(asJSON, asJSONL, asCSV bool) (outputMode, error) {
+	set := 0
+	mode := outPlain
+
+	if asJSON {
+		set++
+		mode = outJSON
+	}
+	if asJSONL {
+		set++
+		mode = outJSONL
+	}
+	if asCSV {
+		set++
+		mode = outCSV
+	}
+
+	if set > 1 {
+		return outPlain, fmt.Errorf("-oJ, -oL and -oC are mutually exclusive")
+	}
+
+	return mode, nil
+}
+
+// selectSources resolves the -sources/-exclude-sources flags against the
+// registry. An empty -sources means "everything", and the two flags are
+// mutually exclusive.
+func selectSources(include, exclude string) ([]assetfind.Source, error) {
+	if include != "" && exclude != "" {
+		return nil, fmt.Errorf("-sources and -exclude-sources can't be used together")
+	}
+
+	if include != "" {
+		var out []assetfind.Source
+		for _, name := range strings.Split(include, ",") {
+			s, ok := assetfind.Lookup(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown source %q (see -list-sources)", name)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+
+	excluded := map[string]bool{}
+	for _, name := range strings.Split(exclude, ",") {
+		if name != "" {
+			excluded[name] = true
+		}
+	}
+
+	var out []assetfind.Source
+	for _, name := range assetfind.SourceNames() {
+		if !excluded[name] {
+			s, _ := assetfind.Lookup(name)
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/tomnomnom/assetfinder/pkg/assetfind"
+)
+
+// outputMode selects how de-duplicated results are rendered. outPlain is
+// the original hostname-per-line format and stays byte-compatible with
+// the old stdout output; the others are for piping into other tools.
+type outputMode int
+
+const (
+	outPlain outputMode = iota
+	outJSON
+	outJSONL
+	outCSV
+)
+
+// writeResults renders results in the given mode. outPlain is handled
+// separately in main (it streams as results arrive); this is only called
+// for the structured modes, once every source has finished.
+func writeResults(w io.Writer, mode outputMode, results []assetfind.Result) error {
+	switch mode {
+	case outJSON:
+		enc := json.NewEncoder(w)
+		return enc.Encode(results)
+	case outJSONL:
+		enc := json.NewEncoder(w)
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outCSV:
+		cw := csv.NewWriter(w)
+		for _, r := range results {
+			sources := ""
+			for i, s := range r.Sources {
+				if i > 0 {
+					sources += "|"
+				}
+				sources += s
+			}
+			if err := cw.Write([]string{r.Host, sources, r.FirstSeen.Format("2006-01-02T15:04:05Z07:00"), strconv.FormatBool(r.Stale)}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown output mode %d", mode)
+	}
+}
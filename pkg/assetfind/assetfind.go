@@ -0,0 +1,113 @@
+// Package assetfind implements passive subdomain enumeration: a registry
+// of Source providers (certificate transparency logs, passive DNS,
+// search engines for hosts) that all feed into one deduplicated stream
+// of Results.
+package assetfind
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Options configures a single Enumerate call.
+type Options struct {
+	// Sources is which providers to query. Use SourceNames/Lookup (or
+	// Register for a custom provider) to build this list.
+	Sources []Source
+
+	// Config supplies credentials and rate limits per source; see
+	// LoadConfig.
+	Config Config
+
+	// Recursive re-feeds every newly discovered subdomain back through
+	// Sources, up to Depth levels deep.
+	Recursive bool
+	Depth     int
+
+	// Active drops any result that doesn't resolve, or that resolves to
+	// its zone's DNS wildcard fingerprint.
+	Active bool
+
+	// Resolver is used for the -active/wildcard checks; nil means the
+	// system resolver. See NewResolver for a custom nameserver list.
+	Resolver *net.Resolver
+
+	// Timeout is the per-request HTTP timeout; zero means
+	// defaultHTTPTimeout.
+	Timeout time.Duration
+
+	// MaxRetries is how many times a 429/5xx response is retried, with
+	// exponential backoff, before giving up or falling back to Cache;
+	// zero means no retries.
+	MaxRetries int
+
+	// Cache is the on-disk response cache to read and fall back to; nil
+	// disables caching entirely. See NewResponseCache.
+	Cache *ResponseCache
+}
+
+// Enumerate streams every subdomain discovered for domain across
+// opts.Sources, deduplicated by host. A Result is emitted each time a
+// host is first seen and again each time another source confirms it, so
+// a consumer that only wants the final state should key by Result.Host
+// and keep the last one seen for each.
+func Enumerate(ctx context.Context, domain string, opts Options) (<-chan Result, error) {
+	if len(opts.Sources) == 0 {
+		return nil, fmt.Errorf("assetfind: no sources configured")
+	}
+
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	client := newHTTPClient(opts.Timeout, opts.MaxRetries, newRateLimiters(opts.Config), opts.Cache)
+	ctx = withHTTPClient(ctx, client)
+
+	maxDepth := 1
+	if opts.Recursive && opts.Depth > 1 {
+		maxDepth = opts.Depth
+	}
+
+	eopts := enumOptions{
+		maxDepth:   maxDepth,
+		maxWorkers: defaultMaxWorkers,
+		active:     opts.Active,
+		resolver:   resolver,
+		wildcards:  newWildcardCache(),
+	}
+
+	sightings := make(chan sighting)
+	go func() {
+		defer close(sightings)
+		runEnumeration(ctx, opts.Sources, opts.Config, domain, eopts, sightings)
+	}()
+
+	results := make(chan Result)
+	go func() {
+		defer close(results)
+
+		seen := make(map[string]*Result)
+		for s := range sightings {
+			r, ok := seen[s.Subdomain]
+			if !ok {
+				r = &Result{Host: s.Subdomain, FirstSeen: s.FirstSeen}
+				seen[s.Subdomain] = r
+			}
+			if !containsString(r.Sources, s.Source) {
+				r.Sources = append(r.Sources, s.Source)
+			}
+			if s.Stale {
+				r.Stale = true
+			}
+
+			emit := *r
+			emit.Sources = append([]string(nil), r.Sources...)
+			results <- emit
+		}
+	}()
+
+	return results, nil
+}
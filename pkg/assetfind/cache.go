@@ -0,0 +1,88 @@
+package assetfind
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type cacheEntry struct {
+	Body      []byte    `json:"body"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// ResponseCache is an on-disk, content-addressed cache for HTTP GETs,
+// keyed by (source, url). It's why repeated recon against the same
+// domain is effectively free, and why crt.sh/CertSpotter rate-limits
+// don't lose you data you already fetched once.
+type ResponseCache struct {
+	dir     string
+	ttl     time.Duration
+	enabled bool
+}
+
+// NewResponseCache opens the cache directory (~/.cache/assetfinder by
+// default). enabled false (set by -no-cache) makes every get/put a no-op
+// without touching the filesystem.
+func NewResponseCache(ttl time.Duration, enabled bool) (*ResponseCache, error) {
+	dir := defaultCacheDir()
+
+	if enabled {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ResponseCache{dir: dir, ttl: ttl, enabled: enabled}, nil
+}
+
+func defaultCacheDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "assetfinder-cache")
+	}
+	return filepath.Join(home, ".cache", "assetfinder")
+}
+
+func (c *ResponseCache) path(source, url string) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the cache entry for (source, url) and whether it's still
+// within ttl. A stale entry is still returned (fresh=false) so callers
+// can fall back to it if the live request fails.
+func (c *ResponseCache) get(source, url string) (*cacheEntry, bool) {
+	if !c.enabled {
+		return nil, false
+	}
+
+	raw, err := ioutil.ReadFile(c.path(source, url))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false
+	}
+
+	return &entry, time.Since(entry.FetchedAt) < c.ttl
+}
+
+func (c *ResponseCache) put(source, url string, body []byte) {
+	if !c.enabled {
+		return
+	}
+
+	raw, err := json.Marshal(cacheEntry{Body: body, FetchedAt: time.Now()})
+	if err != nil {
+		return
+	}
+
+	_ = ioutil.WriteFile(c.path(source, url), raw, 0o644)
+}
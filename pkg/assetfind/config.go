@@ -0,0 +1,86 @@
+package assetfind
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceConfig holds the credentials for a single source, as loaded from
+// the -config file or an environment variable fallback.
+type SourceConfig struct {
+	APIKey    string  `json:"api_key"`
+	APISecret string  `json:"api_secret"`
+	RateLimit float64 `json:"rate_limit"` // requests per second; 0 means unlimited
+}
+
+// Config maps a source name (as returned by Source.Name) to its
+// credentials.
+type Config map[string]SourceConfig
+
+// loadConfig reads a JSON or YAML config file of the form:
+//
+//	{
+//	  "securitytrails": {"api_key": "..."},
+//	  "censys":         {"api_key": "...", "api_secret": "..."}
+//	}
+//
+// The format is chosen by the file extension: ".yml"/".yaml" is parsed as
+// YAML, everything else as JSON.
+//
+// An empty path is not an error; callers get back an empty Config and
+// fall back to environment variables for every source.
+//
+// LoadConfig is the exported entry point; loadConfig does the work so
+// package-internal callers don't need to thread it through twice.
+func LoadConfig(path string) (Config, error) {
+	return loadConfig(path)
+}
+
+func loadConfig(path string) (Config, error) {
+	cfg := Config{}
+
+	if path == "" {
+		return cfg, nil
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		if err := yaml.Unmarshal(raw, &cfg); err != nil {
+			return nil, err
+		}
+	default:
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// forSource returns the SourceConfig for name, falling back to
+// ASSETFINDER_<NAME>_KEY / ASSETFINDER_<NAME>_SECRET environment
+// variables for anything the config file didn't set.
+func (c Config) forSource(name string) SourceConfig {
+	sc := c[name]
+
+	envPrefix := "ASSETFINDER_" + strings.ToUpper(name)
+
+	if sc.APIKey == "" {
+		sc.APIKey = os.Getenv(envPrefix + "_KEY")
+	}
+	if sc.APISecret == "" {
+		sc.APISecret = os.Getenv(envPrefix + "_SECRET")
+	}
+
+	return sc
+}
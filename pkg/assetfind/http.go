@@ -0,0 +1,46 @@
+package assetfind
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+func basicAuth(user, pass string) string {
+	return base64.StdEncoding.EncodeToString([]byte(user + ":" + pass))
+}
+
+// splitLines is a small helper for the sources that return newline- or
+// CSV-delimited text instead of JSON.
+func splitLines(raw []byte) []string {
+	lines := strings.Split(string(raw), "\n")
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// lastComma returns the index of the last comma in s, or -1 if there is none.
+func lastComma(s string) int {
+	return strings.LastIndex(s, ",")
+}
+
+// extractHostsFromHTML does a best-effort scrape of a domain's hostnames
+// out of an HTML table response, for sources like RapidDNS that don't
+// offer a JSON API.
+func extractHostsFromHTML(raw []byte, domain string) []string {
+	out := make([]string, 0)
+	suffix := "." + domain
+
+	for _, field := range strings.Fields(string(raw)) {
+		field = strings.Trim(field, "<>\"',")
+		if strings.HasSuffix(field, suffix) || field == domain {
+			out = append(out, field)
+		}
+	}
+
+	return out
+}
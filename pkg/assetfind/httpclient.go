@@ -0,0 +1,264 @@
+package assetfind
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultHTTPTimeout is the client timeout used when an Options value (or
+// a call that bypasses Enumerate entirely, like a test) doesn't specify
+// its own; unlike MaxRetries, a zero timeout isn't a usable default since
+// it would let a request hang indefinitely.
+const defaultHTTPTimeout = 15 * time.Second
+
+// httpClient is the context-aware HTTP client every source goes through.
+// It retries 429/5xx responses with exponential backoff (honoring a
+// Retry-After header when the server sends one), applies each source's
+// configured rate limit before it ever hits the network, and falls back
+// to the on-disk cache when every retry is exhausted.
+//
+// Enumerate builds one of these per call (see newHTTPClient) and attaches
+// it to that call's context, rather than reusing a single package-level
+// instance, so two concurrent Enumerate calls (or two embedders in the
+// same process) can use different timeouts, retry budgets, rate limits
+// and caches without stepping on each other.
+type httpClient struct {
+	client     *http.Client
+	maxRetries int
+	limiters   *rateLimiters
+	cache      *ResponseCache
+}
+
+// newHTTPClient builds an httpClient from Enumerate's Options fields,
+// falling back to defaultHTTPTimeout when the caller left Timeout unset.
+// maxRetries <= 0 is taken at face value (no retries), matching the
+// -max-retries flag's own semantics.
+func newHTTPClient(timeout time.Duration, maxRetries int, limiters *rateLimiters, cache *ResponseCache) *httpClient {
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	return &httpClient{
+		client:     &http.Client{Timeout: timeout},
+		maxRetries: maxRetries,
+		limiters:   limiters,
+		cache:      cache,
+	}
+}
+
+// defaultHTTPClient is used by httpGet/httpGetWithHeaders/httpGetBasicAuth
+// when ctx has no client attached, e.g. a unit test exercising a fetchX
+// function directly instead of going through Enumerate.
+var defaultHTTPClient = newHTTPClient(0, 0, nil, nil)
+
+type httpClientKey struct{}
+
+// withHTTPClient attaches c to ctx for httpGet/httpGetWithHeaders/
+// httpGetBasicAuth to pick up; Enumerate calls this once per call with a
+// client built from that call's Options.
+func withHTTPClient(ctx context.Context, c *httpClient) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, c)
+}
+
+func httpClientFromContext(ctx context.Context) *httpClient {
+	if c, ok := ctx.Value(httpClientKey{}).(*httpClient); ok {
+		return c
+	}
+	return defaultHTTPClient
+}
+
+// setTransport swaps the default client's http.RoundTripper and returns a
+// func that restores whatever was there before. It exists so tests can
+// point a source's requests at an httptest server or a fake RoundTripper
+// without a live network call, e.g.:
+//
+//	defer setTransport(fakeTransport{...})()
+func setTransport(rt http.RoundTripper) (restore func()) {
+	prev := defaultHTTPClient.client.Transport
+	defaultHTTPClient.client.Transport = rt
+	return func() { defaultHTTPClient.client.Transport = prev }
+}
+
+// staleMarker records whether any request made during one enumerateInto
+// call was served from a stale cache fallback rather than a live fetch.
+// It's threaded through context rather than a return value because a
+// single Source.Enumerate call can make several requests, and any one of
+// them falling back to cache makes that source's whole result set for
+// this host potentially stale.
+type staleMarker struct {
+	mu    sync.Mutex
+	stale bool
+}
+
+type staleMarkerKey struct{}
+
+// withStaleMarker attaches a fresh staleMarker to ctx and returns both, so
+// the caller can inspect marker.stale once the enumeration it wraps has
+// finished.
+func withStaleMarker(ctx context.Context) (context.Context, *staleMarker) {
+	m := &staleMarker{}
+	return context.WithValue(ctx, staleMarkerKey{}, m), m
+}
+
+// markStale flags ctx's staleMarker, if it has one. Enumerate calls made
+// outside of enumerateInto (e.g. directly against a Source in a test)
+// have no marker attached, so this is a no-op for them.
+func markStale(ctx context.Context) {
+	if m, ok := ctx.Value(staleMarkerKey{}).(*staleMarker); ok {
+		m.mu.Lock()
+		m.stale = true
+		m.mu.Unlock()
+	}
+}
+
+func (m *staleMarker) isStale() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stale
+}
+
+func httpGet(ctx context.Context, source, url string) ([]byte, error) {
+	return httpClientFromContext(ctx).do(ctx, source, url, nil)
+}
+
+func httpGetWithHeaders(ctx context.Context, source, url string, headers map[string]string) ([]byte, error) {
+	return httpClientFromContext(ctx).do(ctx, source, url, headers)
+}
+
+func httpGetBasicAuth(ctx context.Context, source, url, user, pass string) ([]byte, error) {
+	return httpGetWithHeaders(ctx, source, url, map[string]string{
+		"Authorization": "Basic " + basicAuth(user, pass),
+	})
+}
+
+func (c *httpClient) do(ctx context.Context, source, url string, headers map[string]string) ([]byte, error) {
+	if c.cache != nil {
+		if entry, fresh := c.cache.get(source, url); fresh {
+			return entry.Body, nil
+		}
+	}
+
+	if c.limiters != nil {
+		if err := c.limiters.wait(ctx, source); err != nil {
+			return nil, err
+		}
+	}
+
+	var lastErr error
+
+	for attempt := 0; ; attempt++ {
+		raw, retryAfter, err := c.attempt(ctx, url, headers)
+		if err == nil {
+			if c.cache != nil {
+				c.cache.put(source, url, raw)
+			}
+			return raw, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil || attempt >= c.maxRetries {
+			if raw, ok := c.fallbackToCache(ctx, source, url, lastErr); ok {
+				return raw, nil
+			}
+			return nil, lastErr
+		}
+		if !sleepBackoff(ctx, attempt, retryAfter) {
+			if raw, ok := c.fallbackToCache(ctx, source, url, lastErr); ok {
+				return raw, nil
+			}
+			return nil, lastErr
+		}
+	}
+}
+
+// fallbackToCache serves a stale cache entry when the upstream request
+// ultimately failed, which is exactly the crt.sh/CertSpotter rate-limit
+// case this cache exists for. It also marks ctx's staleMarker (if any),
+// so callers further up the stack can flag the results this response
+// produces as possibly out of date.
+func (c *httpClient) fallbackToCache(ctx context.Context, source, url string, cause error) ([]byte, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+
+	entry, _ := c.cache.get(source, url)
+	if entry == nil {
+		return nil, false
+	}
+
+	fmt.Fprintf(os.Stderr, "warn: %s: %s, serving cached response from %s\n", source, cause, entry.FetchedAt.Format(time.RFC3339))
+	markStale(ctx)
+	return entry.Body, true
+}
+
+// attempt makes a single request. A non-nil retryAfter duration alongside
+// a non-nil error means the caller should back off and retry.
+func (c *httpClient) attempt(ctx context.Context, url string, headers map[string]string) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		return nil, parseRetryAfter(res.Header.Get("Retry-After")), ErrRateLimited
+	}
+	if res.StatusCode >= 500 {
+		return nil, parseRetryAfter(res.Header.Get("Retry-After")), fmt.Errorf("status %d", res.StatusCode)
+	}
+
+	raw, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return raw, 0, nil
+}
+
+// sleepBackoff waits out an exponential delay (or retryAfter, if set)
+// before the next attempt. It returns false if ctx is cancelled first.
+func sleepBackoff(ctx context.Context, attempt int, retryAfter time.Duration) bool {
+	delay := retryAfter
+	if delay <= 0 {
+		delay = time.Duration(math.Pow(2, float64(attempt)))*time.Second + time.Duration(rand.Intn(250))*time.Millisecond
+	}
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
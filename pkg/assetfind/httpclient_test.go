@@ -0,0 +1,150 @@
+package assetfind
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "30", 30 * time.Second},
+		{"garbage", "not-a-time", 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := parseRetryAfter(c.in); got != c.want {
+				t.Fatalf("parseRetryAfter(%q) = %s, want %s", c.in, got, c.want)
+			}
+		})
+	}
+
+	// An HTTP-date a few seconds out should parse to roughly that many
+	// seconds from now.
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	got := parseRetryAfter(future)
+	if got < 8*time.Second || got > 10*time.Second {
+		t.Fatalf("parseRetryAfter(%q) = %s, want ~10s", future, got)
+	}
+}
+
+func TestSleepBackoffRespectsContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if sleepBackoff(ctx, 0, 5*time.Second) {
+		t.Fatal("expected sleepBackoff to report cancellation, not a completed sleep")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("sleepBackoff took %s to notice ctx cancellation", elapsed)
+	}
+}
+
+func TestSleepBackoffUsesRetryAfterWhenSet(t *testing.T) {
+	start := time.Now()
+	if !sleepBackoff(context.Background(), 0, 30*time.Millisecond) {
+		t.Fatal("expected sleepBackoff to complete")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("sleepBackoff returned after %s, want >= 30ms", elapsed)
+	}
+}
+
+// countingTransport answers with statuses[i] on the i-th request (clamped
+// to the last entry once exhausted), so a test can script a server that
+// fails a fixed number of times before succeeding.
+type countingTransport struct {
+	statuses []int
+	n        int64
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := atomic.AddInt64(&c.n, 1) - 1
+	status := c.statuses[len(c.statuses)-1]
+	if int(i) < len(c.statuses) {
+		status = c.statuses[i]
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte("ok"))),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func (c *countingTransport) calls() int {
+	return int(atomic.LoadInt64(&c.n))
+}
+
+func TestHTTPClientRetriesThenSucceeds(t *testing.T) {
+	transport := &countingTransport{statuses: []int{500, 500, 200}}
+	c := newHTTPClient(time.Second, 2, nil, nil)
+	c.client.Transport = transport
+
+	raw, err := c.do(context.Background(), "src", "http://example.invalid", nil)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if string(raw) != "ok" {
+		t.Fatalf("got body %q, want \"ok\"", raw)
+	}
+	if transport.calls() != 3 {
+		t.Fatalf("got %d requests, want 3 (2 failures + 1 success)", transport.calls())
+	}
+}
+
+func TestHTTPClientGivesUpAfterMaxRetries(t *testing.T) {
+	transport := &countingTransport{statuses: []int{500}}
+	c := newHTTPClient(time.Second, 0, nil, nil)
+	c.client.Transport = transport
+
+	_, err := c.do(context.Background(), "src", "http://example.invalid", nil)
+	if err == nil {
+		t.Fatal("expected an error once retries are exhausted")
+	}
+	if transport.calls() != 1 {
+		t.Fatalf("got %d requests with maxRetries 0, want exactly 1", transport.calls())
+	}
+}
+
+func TestHTTPClientAbortsBackoffOnContextCancellation(t *testing.T) {
+	transport := &countingTransport{statuses: []int{500}}
+	c := newHTTPClient(time.Second, 5, nil, nil)
+	c.client.Transport = transport
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.do(ctx, "src", "http://example.invalid", nil)
+	if err == nil {
+		t.Fatal("expected an error once ctx is cancelled mid-backoff")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("do took %s to notice ctx cancellation during backoff", elapsed)
+	}
+}
+
+func TestHTTPClientRateLimitResponseIsRetried(t *testing.T) {
+	transport := &countingTransport{statuses: []int{429, 200}}
+	c := newHTTPClient(time.Second, 2, nil, nil)
+	c.client.Transport = transport
+
+	raw, err := c.do(context.Background(), "src", "http://example.invalid", nil)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if string(raw) != "ok" {
+		t.Fatalf("got body %q, want \"ok\"", raw)
+	}
+}
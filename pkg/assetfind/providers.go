@@ -1,84 +1,18 @@
-package main
+package assetfind
 
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
-	"flag"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"os"
 	"strings"
-	"sync"
 )
 
-func main() {
-	flag.Parse()
-
-	domain := flag.Arg(0)
-	if domain == "" {
-		fmt.Println("no domain specified")
-		return
-	}
-
-	sources := []fetchFn{
-		fetchCertSpotter,
-		fetchHackerTarget,
-		fetchThreatCrowd,
-		fetchCrtSh,
-		fetchFacebook,
-	}
-
-	out := make(chan string)
-	var wg sync.WaitGroup
-
-	// call each of the source workers in a goroutine
-	for _, source := range sources {
-		wg.Add(1)
-		fn := source
-
-		go func() {
-			defer wg.Done()
-
-			names, err := fn(domain)
-
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "err: %s\n", err)
-				return
-			}
-
-			for _, n := range names {
-				out <- n
-			}
-		}()
-	}
-
-	// close the output channel when all the workers are done
-	go func() {
-		wg.Wait()
-		close(out)
-	}()
-
-	// track what we've already printed to avoid duplicates
-	printed := make(map[string]bool)
-
-	for n := range out {
-		n = cleanDomain(n)
-		if _, ok := printed[n]; ok {
-			continue
-		}
-		fmt.Println(n)
-		printed[n] = true
-	}
-}
-
-type fetchFn func(string) ([]string, error)
-
-func fetchThreatCrowd(domain string) ([]string, error) {
+func fetchThreatCrowd(ctx context.Context, domain string) ([]string, error) {
 	out := make([]string, 0)
 
-	raw, err := httpGet(
+	raw, err := httpGet(ctx, "threatcrowd",
 		fmt.Sprintf("https://www.threatcrowd.org/searchApi/v2/domain/report/?domain=%s", domain),
 	)
 	if err != nil {
@@ -98,10 +32,10 @@ func fetchThreatCrowd(domain string) ([]string, error) {
 	return out, nil
 }
 
-func fetchHackerTarget(domain string) ([]string, error) {
+func fetchHackerTarget(ctx context.Context, domain string) ([]string, error) {
 	out := make([]string, 0)
 
-	raw, err := httpGet(
+	raw, err := httpGet(ctx, "hackertarget",
 		fmt.Sprintf("https://api.hackertarget.com/hostsearch/?q=%s", domain),
 	)
 	if err != nil {
@@ -121,10 +55,10 @@ func fetchHackerTarget(domain string) ([]string, error) {
 	return out, sc.Err()
 }
 
-func fetchCertSpotter(domain string) ([]string, error) {
+func fetchCertSpotter(ctx context.Context, domain string) ([]string, error) {
 	out := make([]string, 0)
 
-	raw, err := httpGet(
+	raw, err := httpGet(ctx, "certspotter",
 		fmt.Sprintf("https://certspotter.com/api/v0/certs?domain=%s", domain),
 	)
 	if err != nil {
@@ -146,19 +80,17 @@ func fetchCertSpotter(domain string) ([]string, error) {
 	return out, nil
 }
 
-func fetchCrtSh(domain string) ([]string, error) {
-	resp, err := http.Get(
+func fetchCrtSh(ctx context.Context, domain string) ([]string, error) {
+	raw, err := httpGet(ctx, "crtsh",
 		fmt.Sprintf("https://crt.sh/?q=%%25.%s&output=json", domain),
 	)
 	if err != nil {
 		return []string{}, err
 	}
-	defer resp.Body.Close()
 
 	output := make([]string, 0)
 
-	dec := json.NewDecoder(resp.Body)
-
+	dec := json.NewDecoder(bytes.NewReader(raw))
 	for {
 		wrapper := struct {
 			Name string `json:"name_value"`
@@ -174,22 +106,35 @@ func fetchCrtSh(domain string) ([]string, error) {
 	return output, nil
 }
 
-func httpGet(url string) ([]byte, error) {
-	res, err := http.Get(url)
+func fetchFacebook(ctx context.Context, domain, appID, appSecret string) ([]string, error) {
+	out := make([]string, 0)
+
+	raw, err := httpGet(ctx, "facebook", fmt.Sprintf(
+		"https://graph.facebook.com/certificates?query=%s&fields=domains&access_token=%s|%s",
+		domain, appID, appSecret,
+	))
 	if err != nil {
-		return []byte{}, err
+		return out, err
 	}
 
-	raw, err := ioutil.ReadAll(res.Body)
+	wrapper := struct {
+		Data []struct {
+			Domains []string `json:"domains"`
+		} `json:"data"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return out, err
+	}
 
-	res.Body.Close()
-	if err != nil {
-		return []byte{}, err
+	for _, d := range wrapper.Data {
+		out = append(out, d.Domains...)
 	}
 
-	return raw, nil
+	return out, nil
 }
 
+// cleanDomain normalizes a raw name as returned by a source: lowercased,
+// with any leading wildcard/percent-escape or stray dot stripped.
 func cleanDomain(d string) string {
 	d = strings.ToLower(d)
 
@@ -207,5 +152,4 @@ func cleanDomain(d string) string {
 	}
 
 	return d
-
 }
@@ -0,0 +1,347 @@
+package assetfind
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// The sources in this file all require credentials, supplied via -config
+// or an ASSETFINDER_<NAME>_KEY environment variable (see config.go).
+// They're kept separate from the free sources in sources.go since they
+// share the "bail out with ErrAuthRequired if cfg.APIKey is empty" shape.
+
+type securityTrailsSource struct{}
+
+func (securityTrailsSource) Name() string       { return "securitytrails" }
+func (securityTrailsSource) RequiresAuth() bool { return true }
+func (s securityTrailsSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrAuthRequired
+	}
+
+	raw, err := httpGetWithHeaders(ctx, s.Name(),
+		fmt.Sprintf("https://api.securitytrails.com/v1/domain/%s/subdomains", domain),
+		map[string]string{"apikey": cfg.APIKey},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		Subdomains []string `json:"subdomains"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(wrapper.Subdomains))
+	for _, sub := range wrapper.Subdomains {
+		out = append(out, fmt.Sprintf("%s.%s", sub, domain))
+	}
+
+	return asChannel(out, nil)
+}
+
+type virusTotalSource struct{}
+
+func (virusTotalSource) Name() string       { return "virustotal" }
+func (virusTotalSource) RequiresAuth() bool { return true }
+func (s virusTotalSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrAuthRequired
+	}
+
+	raw, err := httpGet(ctx, s.Name(), fmt.Sprintf(
+		"https://www.virustotal.com/vtapi/v2/domain/report?apikey=%s&domain=%s", cfg.APIKey, domain,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		Subdomains []string `json:"subdomains"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	return asChannel(wrapper.Subdomains, nil)
+}
+
+type shodanSource struct{}
+
+func (shodanSource) Name() string       { return "shodan" }
+func (shodanSource) RequiresAuth() bool { return true }
+func (s shodanSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrAuthRequired
+	}
+
+	raw, err := httpGet(ctx, s.Name(), fmt.Sprintf("https://api.shodan.io/dns/domain/%s?key=%s", domain, cfg.APIKey))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		Subdomains []string `json:"subdomains"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(wrapper.Subdomains))
+	for _, sub := range wrapper.Subdomains {
+		out = append(out, fmt.Sprintf("%s.%s", sub, domain))
+	}
+
+	return asChannel(out, nil)
+}
+
+type censysSource struct{}
+
+func (censysSource) Name() string       { return "censys" }
+func (censysSource) RequiresAuth() bool { return true }
+func (s censysSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" || cfg.APISecret == "" {
+		return nil, ErrAuthRequired
+	}
+
+	raw, err := httpGetBasicAuth(ctx, s.Name(),
+		fmt.Sprintf("https://search.censys.io/api/v2/hosts/search?q=%s", domain),
+		cfg.APIKey, cfg.APISecret,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		Result struct {
+			Hits []struct {
+				Names []string `json:"names"`
+			} `json:"hits"`
+		} `json:"result"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0)
+	for _, hit := range wrapper.Result.Hits {
+		out = append(out, hit.Names...)
+	}
+
+	return asChannel(out, nil)
+}
+
+type passiveTotalSource struct{}
+
+func (passiveTotalSource) Name() string       { return "passivetotal" }
+func (passiveTotalSource) RequiresAuth() bool { return true }
+func (s passiveTotalSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" || cfg.APISecret == "" {
+		return nil, ErrAuthRequired
+	}
+
+	raw, err := httpGetBasicAuth(ctx, s.Name(),
+		fmt.Sprintf("https://api.riskiq.net/pt/v2/enrichment/subdomains?query=%s", domain),
+		cfg.APIKey, cfg.APISecret,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		Subdomains []string `json:"subdomains"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(wrapper.Subdomains))
+	for _, sub := range wrapper.Subdomains {
+		out = append(out, fmt.Sprintf("%s.%s", sub, domain))
+	}
+
+	return asChannel(out, nil)
+}
+
+type binaryEdgeSource struct{}
+
+func (binaryEdgeSource) Name() string       { return "binaryedge" }
+func (binaryEdgeSource) RequiresAuth() bool { return true }
+func (s binaryEdgeSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrAuthRequired
+	}
+
+	raw, err := httpGetWithHeaders(ctx, s.Name(),
+		fmt.Sprintf("https://api.binaryedge.io/v2/query/domains/subdomain/%s", domain),
+		map[string]string{"X-Key": cfg.APIKey},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		Events []string `json:"events"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	return asChannel(wrapper.Events, nil)
+}
+
+type dnsdbSource struct{}
+
+func (dnsdbSource) Name() string       { return "dnsdb" }
+func (dnsdbSource) RequiresAuth() bool { return true }
+func (s dnsdbSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrAuthRequired
+	}
+
+	raw, err := httpGetWithHeaders(ctx, s.Name(),
+		fmt.Sprintf("https://api.dnsdb.info/lookup/rrset/name/*.%s?swclient=assetfinder", domain),
+		map[string]string{"X-API-Key": cfg.APIKey, "Accept": "application/json"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0)
+	for _, line := range splitLines(raw) {
+		rec := struct {
+			Name string `json:"rrname"`
+		}{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			continue
+		}
+		out = append(out, rec.Name)
+	}
+
+	return asChannel(out, nil)
+}
+
+type bufferOverSource struct{}
+
+func (bufferOverSource) Name() string       { return "bufferover" }
+func (bufferOverSource) RequiresAuth() bool { return false }
+func (s bufferOverSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	raw, err := httpGet(ctx, s.Name(), fmt.Sprintf("https://dns.bufferover.run/dns?q=.%s", domain))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		FDNSA []string `json:"FDNS_A"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(wrapper.FDNSA))
+	for _, rec := range wrapper.FDNSA {
+		// records come back as "ip,hostname"
+		if idx := lastComma(rec); idx != -1 {
+			out = append(out, rec[idx+1:])
+		}
+	}
+
+	return asChannel(out, nil)
+}
+
+type alienVaultSource struct{}
+
+func (alienVaultSource) Name() string       { return "alienvault" }
+func (alienVaultSource) RequiresAuth() bool { return false }
+func (s alienVaultSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	headers := map[string]string{}
+	if cfg.APIKey != "" {
+		headers["X-OTX-API-KEY"] = cfg.APIKey
+	}
+
+	raw, err := httpGetWithHeaders(ctx, s.Name(),
+		fmt.Sprintf("https://otx.alienvault.com/api/v1/indicators/domain/%s/passive_dns", domain),
+		headers,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		PassiveDNS []struct {
+			Hostname string `json:"hostname"`
+		} `json:"passive_dns"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(wrapper.PassiveDNS))
+	for _, rec := range wrapper.PassiveDNS {
+		out = append(out, rec.Hostname)
+	}
+
+	return asChannel(out, nil)
+}
+
+type riddlerSource struct{}
+
+func (riddlerSource) Name() string       { return "riddler" }
+func (riddlerSource) RequiresAuth() bool { return false }
+func (s riddlerSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	raw, err := httpGet(ctx, s.Name(), fmt.Sprintf("https://riddler.io/search/exportcsv?q=pld:%s", domain))
+	if err != nil {
+		return nil, err
+	}
+
+	return asChannel(splitLines(raw), nil)
+}
+
+type chaosSource struct{}
+
+func (chaosSource) Name() string       { return "chaos" }
+func (chaosSource) RequiresAuth() bool { return true }
+func (s chaosSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrAuthRequired
+	}
+
+	raw, err := httpGetWithHeaders(ctx, s.Name(),
+		fmt.Sprintf("https://dns.projectdiscovery.io/dns/%s/subdomains", domain),
+		map[string]string{"Authorization": cfg.APIKey},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		Domain     string   `json:"domain"`
+		Subdomains []string `json:"subdomains"`
+	}{}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(wrapper.Subdomains))
+	for _, sub := range wrapper.Subdomains {
+		out = append(out, fmt.Sprintf("%s.%s", sub, wrapper.Domain))
+	}
+
+	return asChannel(out, nil)
+}
+
+type rapidDNSSource struct{}
+
+func (rapidDNSSource) Name() string       { return "rapiddns" }
+func (rapidDNSSource) RequiresAuth() bool { return false }
+func (s rapidDNSSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	raw, err := httpGet(ctx, s.Name(), fmt.Sprintf("https://rapiddns.io/subdomain/%s?full=1", domain))
+	if err != nil {
+		return nil, err
+	}
+
+	return asChannel(extractHostsFromHTML(raw, domain), nil)
+}
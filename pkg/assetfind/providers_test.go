@@ -0,0 +1,65 @@
+package assetfind
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+// roundTripperFunc lets a plain function satisfy http.RoundTripper, so a
+// test can fake an upstream API's response without a live network call.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func fakeJSONResponse(body string) roundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			Header:     make(http.Header),
+		}, nil
+	}
+}
+
+func TestFetchThreatCrowd(t *testing.T) {
+	defer setTransport(fakeJSONResponse(`{"subdomains": ["www.example.com", "api.example.com"]}`))()
+
+	got, err := fetchThreatCrowd(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("fetchThreatCrowd: %v", err)
+	}
+
+	want := []string{"www.example.com", "api.example.com"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFetchHackerTarget(t *testing.T) {
+	defer setTransport(fakeJSONResponse("www.example.com,1.2.3.4\napi.example.com,5.6.7.8\n"))()
+
+	got, err := fetchHackerTarget(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("fetchHackerTarget: %v", err)
+	}
+
+	want := []string{"www.example.com", "api.example.com"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package assetfind
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a minimal token-bucket rate limiter: it holds at most
+// rate tokens and refills at rate tokens/sec.
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	rate     float64
+	lastFill time.Time
+}
+
+func newTokenBucket(ratePerSec float64) *tokenBucket {
+	return &tokenBucket{tokens: ratePerSec, rate: ratePerSec, lastFill: time.Now()}
+}
+
+// wait blocks until a token is available or ctx is cancelled.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.lastFill).Seconds() * b.rate
+		if b.tokens > b.rate {
+			b.tokens = b.rate
+		}
+		b.lastFill = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// rateLimiters holds one tokenBucket per source that has a configured
+// rate limit; sources without one aren't throttled at all.
+type rateLimiters struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	cfg     Config
+}
+
+func newRateLimiters(cfg Config) *rateLimiters {
+	return &rateLimiters{buckets: make(map[string]*tokenBucket), cfg: cfg}
+}
+
+func (r *rateLimiters) wait(ctx context.Context, source string) error {
+	r.mu.Lock()
+	b, ok := r.buckets[source]
+	if !ok {
+		if limit := r.cfg.forSource(source).RateLimit; limit > 0 {
+			b = newTokenBucket(limit)
+		}
+		r.buckets[source] = b
+	}
+	r.mu.Unlock()
+
+	if b == nil {
+		return nil
+	}
+
+	return b.wait(ctx)
+}
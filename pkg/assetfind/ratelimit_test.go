@@ -0,0 +1,63 @@
+package assetfind
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketWaitThrottlesToRate(t *testing.T) {
+	b := newTokenBucket(10) // 10/sec, i.e. one token every 100ms
+
+	ctx := context.Background()
+	// The bucket starts full (one burst of `rate` tokens), so the first
+	// wait is immediate.
+	if err := b.wait(ctx); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+
+	// Drain the rest of the initial burst.
+	for i := 0; i < 9; i++ {
+		if err := b.wait(ctx); err != nil {
+			t.Fatalf("burst wait %d: %v", i, err)
+		}
+	}
+
+	start := time.Now()
+	if err := b.wait(ctx); err != nil {
+		t.Fatalf("throttled wait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected the bucket to throttle once empty, only waited %s", elapsed)
+	}
+}
+
+func TestTokenBucketWaitRespectsContextCancellation(t *testing.T) {
+	b := newTokenBucket(1) // one token/sec, so the bucket runs dry fast
+	ctx := context.Background()
+	if err := b.wait(ctx); err != nil {
+		t.Fatalf("priming wait: %v", err)
+	}
+
+	cctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := b.wait(cctx); err == nil {
+		t.Fatal("expected wait to return an error once ctx is cancelled")
+	}
+}
+
+func TestRateLimitersOnlyThrottlesConfiguredSources(t *testing.T) {
+	cfg := Config{"slow": SourceConfig{RateLimit: 5}}
+	limiters := newRateLimiters(cfg)
+
+	// "fast" has no configured rate limit, so it should never block, no
+	// matter how many times it's called.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 100; i++ {
+		if err := limiters.wait(ctx, "fast"); err != nil {
+			t.Fatalf("unthrottled source blocked on call %d: %v", i, err)
+		}
+	}
+}
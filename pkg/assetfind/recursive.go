@@ -0,0 +1,108 @@
+package assetfind
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultMaxWorkers bounds how many hosts get re-enumerated at once during
+// a recursive run, so a big result set doesn't turn into maxDepth * N
+// simultaneous calls against every source's API.
+const defaultMaxWorkers = 20
+
+// enumOptions bundles the knobs that control how deep and how carefully
+// enumeration goes, beyond which sources to query.
+type enumOptions struct {
+	maxDepth   int
+	maxWorkers int
+	active     bool
+	resolver   hostResolver
+	wildcards  *wildcardCache
+}
+
+// runEnumeration feeds domain through sources, then, if maxDepth > 1,
+// re-feeds every newly discovered subdomain back through the same sources
+// as its own "domain", up to maxDepth levels deep. A plain, non-recursive
+// run is just the maxDepth == 1 case of this same loop.
+func runEnumeration(ctx context.Context, sources []Source, cfg Config, domain string, opts enumOptions, out chan<- sighting) {
+	sem := make(chan struct{}, opts.maxWorkers)
+	fedAsFrontier := map[string]bool{domain: true}
+	frontier := []string{domain}
+
+	for depth := 0; depth < opts.maxDepth && len(frontier) > 0; depth++ {
+		discovered := make(chan sighting)
+		var wg sync.WaitGroup
+
+		for _, host := range frontier {
+			wg.Add(1)
+			sem <- struct{}{}
+
+			go func(host string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				enumerateInto(ctx, sources, cfg, host, discovered)
+			}(host)
+		}
+
+		go func() {
+			wg.Wait()
+			close(discovered)
+		}()
+
+		var next []string
+		for r := range discovered {
+			if opts.active && !passesActiveFilter(ctx, opts.resolver, opts.wildcards, r.Subdomain) {
+				continue
+			}
+			out <- r
+
+			if !fedAsFrontier[r.Subdomain] {
+				fedAsFrontier[r.Subdomain] = true
+				next = append(next, r.Subdomain)
+			}
+		}
+
+		frontier = next
+	}
+}
+
+// enumerateInto runs every source against host and forwards each result
+// onto discovered; shared by the first pass and recursive re-feeding.
+func enumerateInto(ctx context.Context, sources []Source, cfg Config, host string, discovered chan<- sighting) {
+	var wg sync.WaitGroup
+
+	for _, source := range sources {
+		wg.Add(1)
+		src := source
+
+		go func() {
+			defer wg.Done()
+
+			sctx, marker := withStaleMarker(ctx)
+			names, err := src.Enumerate(sctx, host, cfg.forSource(src.Name()))
+			if err != nil {
+				switch {
+				case ctx.Err() != nil:
+					// context cancelled/timed out; nothing new to report.
+				case err == ErrAuthRequired:
+					fmt.Fprintf(os.Stderr, "auth: %s: %s\n", src.Name(), err)
+				case err == ErrRateLimited:
+					fmt.Fprintf(os.Stderr, "ratelimit: %s: %s\n", src.Name(), err)
+				default:
+					fmt.Fprintf(os.Stderr, "err: %s: %s\n", src.Name(), err)
+				}
+				return
+			}
+
+			stale := marker.isStale()
+			for n := range names {
+				discovered <- sighting{Subdomain: cleanDomain(n), Source: src.Name(), FirstSeen: time.Now(), Stale: stale}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,118 @@
+package assetfind
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// childSource reports, for any host it's asked about, a single child
+// subdomain one level deeper ("example.com" -> "sub.example.com" ->
+// "sub.sub.example.com" -> ...), so it can drive runEnumeration's depth
+// limit deterministically.
+type childSource struct{}
+
+func (childSource) Name() string       { return "child" }
+func (childSource) RequiresAuth() bool { return false }
+func (childSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	out := make(chan string, 1)
+	out <- "sub." + domain
+	close(out)
+	return out, nil
+}
+
+func TestRunEnumerationRespectsMaxDepth(t *testing.T) {
+	out := make(chan sighting)
+	var got []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for s := range out {
+			got = append(got, s.Subdomain)
+		}
+	}()
+
+	opts := enumOptions{maxDepth: 3, maxWorkers: defaultMaxWorkers, wildcards: newWildcardCache()}
+	runEnumeration(context.Background(), []Source{childSource{}}, Config{}, "example.com", opts, out)
+	close(out)
+	<-done
+
+	// One new host per depth: sub.example.com, sub.sub.example.com,
+	// sub.sub.sub.example.com. A fourth level would mean maxDepth wasn't
+	// enforced.
+	if len(got) != 3 {
+		t.Fatalf("got %d results with maxDepth 3: %v", len(got), got)
+	}
+	deepest := "sub.sub.sub.example.com"
+	found := false
+	for _, h := range got {
+		if h == deepest {
+			found = true
+		}
+		if len(h) > len(deepest) {
+			t.Fatalf("result %q is deeper than maxDepth should allow", h)
+		}
+	}
+	if !found {
+		t.Fatalf("expected %q among results, got %v", deepest, got)
+	}
+}
+
+// fanOutThenTrackSource emits 50 children the first time it's asked about
+// "example.com", then for every subsequent (re-fed) host just tracks how
+// many Enumerate calls against it are in flight at once.
+type fanOutThenTrackSource struct {
+	cur, max int64
+}
+
+func (s *fanOutThenTrackSource) Name() string       { return "fanout" }
+func (s *fanOutThenTrackSource) RequiresAuth() bool { return false }
+func (s *fanOutThenTrackSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if domain == "example.com" {
+		out := make(chan string, 50)
+		for i := 0; i < 50; i++ {
+			out <- fmt.Sprintf("h%d.example.com", i)
+		}
+		close(out)
+		return out, nil
+	}
+
+	n := atomic.AddInt64(&s.cur, 1)
+	for {
+		m := atomic.LoadInt64(&s.max)
+		if n <= m || atomic.CompareAndSwapInt64(&s.max, m, n) {
+			break
+		}
+	}
+	atomic.AddInt64(&s.cur, -1)
+
+	out := make(chan string)
+	close(out)
+	return out, nil
+}
+
+// TestRunEnumerationBoundsWorkerConcurrency confirms opts.maxWorkers caps
+// how many re-fed hosts are enumerated concurrently, not just how many
+// sources run per host.
+func TestRunEnumerationBoundsWorkerConcurrency(t *testing.T) {
+	const maxWorkers = 4
+
+	src := &fanOutThenTrackSource{}
+	out := make(chan sighting)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range out {
+		}
+	}()
+
+	opts := enumOptions{maxDepth: 2, maxWorkers: maxWorkers, wildcards: newWildcardCache()}
+	runEnumeration(context.Background(), []Source{src}, Config{}, "example.com", opts, out)
+	close(out)
+	<-done
+
+	if got := atomic.LoadInt64(&src.max); got > maxWorkers {
+		t.Fatalf("observed %d concurrent Enumerate calls, want <= %d", got, maxWorkers)
+	}
+}
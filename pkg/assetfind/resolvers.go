@@ -0,0 +1,64 @@
+package assetfind
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// NewResolver builds a *net.Resolver that dials the nameservers listed in
+// path, one per line, round-robin. An empty path means "use the system
+// resolver".
+func NewResolver(path string) (*net.Resolver, error) {
+	return newResolver(path)
+}
+
+func newResolver(path string) (*net.Resolver, error) {
+	if path == "" {
+		return net.DefaultResolver, nil
+	}
+
+	addrs, err := readResolvers(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no resolvers found in %s", path)
+	}
+
+	// LookupHost dials the A and AAAA queries concurrently, so next is
+	// incremented from multiple goroutines at once; atomic keeps the
+	// round-robin split even under that concurrency.
+	var next uint64
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			i := atomic.AddUint64(&next, 1) - 1
+			addr := addrs[i%uint64(len(addrs))]
+
+			d := net.Dialer{Timeout: 5 * time.Second}
+			return d.DialContext(ctx, network, net.JoinHostPort(addr, "53"))
+		},
+	}, nil
+}
+
+func readResolvers(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if line := sc.Text(); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out, sc.Err()
+}
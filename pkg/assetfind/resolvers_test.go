@@ -0,0 +1,42 @@
+package assetfind
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestNewResolverDialIsRaceFree hits the Dial closure built by newResolver
+// from many goroutines at once, the same way net.Resolver's PreferGo mode
+// dials the A and AAAA queries for a single LookupHost call concurrently.
+// Run with -race: it's how d753ce1 caught the round-robin counter race in
+// the first place.
+func TestNewResolverDialIsRaceFree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resolvers.txt")
+	if err := os.WriteFile(path, []byte("127.0.0.1\n127.0.0.2\n127.0.0.3\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	resolver, err := newResolver(path)
+	if err != nil {
+		t.Fatalf("newResolver: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			// Nothing is listening on these addresses, so this errors out
+			// (or times out) quickly; what matters is exercising the
+			// round-robin counter concurrently, not the dial outcome.
+			_, _ = resolver.Dial(ctx, "udp", "ignored:53")
+		}()
+	}
+	wg.Wait()
+}
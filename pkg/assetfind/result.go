@@ -0,0 +1,41 @@
+package assetfind
+
+import "time"
+
+// sighting is a single report of a subdomain from one source, as produced
+// by the enumeration pipeline before Enumerate merges it into a Result.
+type sighting struct {
+	Subdomain string
+	Source    string
+	FirstSeen time.Time
+
+	// Stale is true if the source's data came from a stale on-disk cache
+	// entry served because the live upstream request failed (see
+	// httpClient.fallbackToCache), not from a fresh fetch.
+	Stale bool
+}
+
+// Result is the de-duplicated view of a subdomain: every source that has
+// reported it so far, and when it was first seen. Enumerate emits a new
+// Result each time a host is discovered or gains another source, so the
+// Sources slice only ever grows across a given host's emissions.
+type Result struct {
+	Host      string    `json:"host"`
+	Sources   []string  `json:"sources"`
+	FirstSeen time.Time `json:"first_seen"`
+
+	// Stale is true once any contributing source served a cached
+	// response from a prior run instead of a live fetch, so a consumer
+	// of the structured output formats can tell this data may be out of
+	// date rather than treating it as confirmed fresh.
+	Stale bool `json:"stale"`
+}
+
+func containsString(ss []string, s string) bool {
+	for _, existing := range ss {
+		if existing == s {
+			return true
+		}
+	}
+	return false
+}
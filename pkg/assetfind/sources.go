@@ -0,0 +1,145 @@
+package assetfind
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+// ErrAuthRequired is returned by a Source when it needs an API key that
+// wasn't supplied via -config or the environment. main filters these out
+// of the default stderr noise so running without every key configured
+// doesn't look like a string of failures.
+var ErrAuthRequired = errors.New("source requires an API key")
+
+// ErrRateLimited is returned by a Source when the upstream API has
+// throttled the request.
+var ErrRateLimited = errors.New("source is rate limited")
+
+// Source is implemented by every passive-recon provider. Enumerate streams
+// subdomains onto the returned channel and closes it when done; it should
+// respect ctx cancellation for sources that make multiple requests.
+type Source interface {
+	Name() string
+	RequiresAuth() bool
+	Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error)
+}
+
+// registry holds every known Source, keyed by Name().
+var registry = map[string]Source{}
+
+func register(s Source) {
+	registry[s.Name()] = s
+}
+
+// Register adds a Source to the registry under its Name(), so that
+// callers embedding this package can plug in their own providers (an
+// internal passive-DNS feed, say) alongside the built-in ones.
+func Register(s Source) {
+	register(s)
+}
+
+// Lookup returns the registered Source with the given name, if any.
+func Lookup(name string) (Source, bool) {
+	s, ok := registry[name]
+	return s, ok
+}
+
+// SourceNames returns every registered source name, sorted.
+func SourceNames() []string {
+	return sourceNames()
+}
+
+func init() {
+	register(certSpotterSource{})
+	register(hackerTargetSource{})
+	register(threatCrowdSource{})
+	register(crtShSource{})
+	register(facebookSource{})
+	register(securityTrailsSource{})
+	register(virusTotalSource{})
+	register(shodanSource{})
+	register(censysSource{})
+	register(passiveTotalSource{})
+	register(binaryEdgeSource{})
+	register(dnsdbSource{})
+	register(bufferOverSource{})
+	register(alienVaultSource{})
+	register(riddlerSource{})
+	register(chaosSource{})
+	register(rapidDNSSource{})
+}
+
+// sourceNames returns every registered source name, sorted, for -list-sources.
+func sourceNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// asChannel adapts the old slice-returning fetch functions to the
+// streaming Source interface without having to rewrite each of them.
+func asChannel(names []string, err error) (<-chan string, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan string, len(names))
+	for _, n := range names {
+		out <- n
+	}
+	close(out)
+
+	return out, nil
+}
+
+type certSpotterSource struct{}
+
+func (certSpotterSource) Name() string         { return "certspotter" }
+func (certSpotterSource) RequiresAuth() bool    { return false }
+func (certSpotterSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	names, err := fetchCertSpotter(ctx, domain)
+	return asChannel(names, err)
+}
+
+type hackerTargetSource struct{}
+
+func (hackerTargetSource) Name() string      { return "hackertarget" }
+func (hackerTargetSource) RequiresAuth() bool { return false }
+func (hackerTargetSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	names, err := fetchHackerTarget(ctx, domain)
+	return asChannel(names, err)
+}
+
+type threatCrowdSource struct{}
+
+func (threatCrowdSource) Name() string       { return "threatcrowd" }
+func (threatCrowdSource) RequiresAuth() bool { return false }
+func (threatCrowdSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	names, err := fetchThreatCrowd(ctx, domain)
+	return asChannel(names, err)
+}
+
+type crtShSource struct{}
+
+func (crtShSource) Name() string       { return "crtsh" }
+func (crtShSource) RequiresAuth() bool { return false }
+func (crtShSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	names, err := fetchCrtSh(ctx, domain)
+	return asChannel(names, err)
+}
+
+type facebookSource struct{}
+
+func (facebookSource) Name() string       { return "facebook" }
+func (facebookSource) RequiresAuth() bool { return true }
+func (facebookSource) Enumerate(ctx context.Context, domain string, cfg SourceConfig) (<-chan string, error) {
+	if cfg.APIKey == "" || cfg.APISecret == "" {
+		return nil, ErrAuthRequired
+	}
+	names, err := fetchFacebook(ctx, domain, cfg.APIKey, cfg.APISecret)
+	return asChannel(names, err)
+}
@@ -0,0 +1,177 @@
+package assetfind
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// hostResolver is the subset of *net.Resolver this file needs. It exists
+// so tests can fake DNS answers (including a CNAME-only wildcard match)
+// without standing up a real nameserver; *net.Resolver satisfies it as-is.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// wildcardFingerprint is the set of addresses (and, where present, the
+// CNAME) a zone's DNS wildcard resolves every nonexistent label to. A
+// wildcard that points at a CNAME with rotating backend IPs (a load
+// balancer or CDN) would otherwise look like "no wildcard" if only the
+// resolved A/AAAA addresses were compared across probes.
+type wildcardFingerprint struct {
+	addrs []string
+	cname string
+}
+
+// wildcardCache remembers each zone's wildcard fingerprint (or the fact
+// that it has none) so a zone is only probed once per run, no matter how
+// many of its subdomains get resolved.
+type wildcardCache struct {
+	mu    sync.Mutex
+	zones map[string]*wildcardFingerprint // nil value means "no wildcard"
+}
+
+func newWildcardCache() *wildcardCache {
+	return &wildcardCache{zones: make(map[string]*wildcardFingerprint)}
+}
+
+// fingerprint returns zone's wildcard fingerprint, probing it the first
+// time it's seen and caching the result (including a nil "no wildcard"
+// result) for every later call.
+func (c *wildcardCache) fingerprint(ctx context.Context, resolver hostResolver, zone string) *wildcardFingerprint {
+	c.mu.Lock()
+	if fp, ok := c.zones[zone]; ok {
+		c.mu.Unlock()
+		return fp
+	}
+	c.mu.Unlock()
+
+	fp := probeWildcard(ctx, resolver, zone)
+
+	c.mu.Lock()
+	c.zones[zone] = fp
+	c.mu.Unlock()
+
+	return fp
+}
+
+const wildcardProbes = 3
+
+// probeWildcard resolves a handful of random, almost-certainly-nonexistent
+// labels under zone. If they all come back with the same address set,
+// that's the zone's wildcard fingerprint; if any of them fails to resolve,
+// or they disagree, the zone has no wildcard.
+func probeWildcard(ctx context.Context, resolver hostResolver, zone string) *wildcardFingerprint {
+	var fps []*wildcardFingerprint
+
+	for i := 0; i < wildcardProbes; i++ {
+		label := fmt.Sprintf("%s.%s", randomLabel(), zone)
+
+		addrs, err := resolveHost(ctx, resolver, label)
+		if err != nil || len(addrs) == 0 {
+			return nil
+		}
+		fps = append(fps, &wildcardFingerprint{addrs: addrs, cname: lookupCNAME(ctx, resolver, label)})
+	}
+
+	first := fps[0]
+	for _, fp := range fps[1:] {
+		if !sameFingerprint(fp, first) {
+			return nil
+		}
+	}
+
+	return first
+}
+
+// passesActiveFilter reports whether host should survive -active: it must
+// resolve, and its addresses must not match its zone's wildcard
+// fingerprint (a match means it's indistinguishable from any other
+// random label under that zone, so it isn't a real, distinct host).
+func passesActiveFilter(ctx context.Context, resolver hostResolver, wc *wildcardCache, host string) bool {
+	addrs, err := resolveHost(ctx, resolver, host)
+	if err != nil || len(addrs) == 0 {
+		return false
+	}
+
+	fp := wc.fingerprint(ctx, resolver, parentZone(host))
+	if fp != nil && sameFingerprint(&wildcardFingerprint{addrs: addrs, cname: lookupCNAME(ctx, resolver, host)}, fp) {
+		return false
+	}
+
+	return true
+}
+
+func resolveHost(ctx context.Context, resolver hostResolver, host string) ([]string, error) {
+	addrs, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(addrs)
+	return addrs, nil
+}
+
+// lookupCNAME returns host's canonical name, or "" if host has no CNAME
+// (or the lookup fails, which is treated the same as "no CNAME" since a
+// CNAME is only used to strengthen the fingerprint, not gate it).
+func lookupCNAME(ctx context.Context, resolver hostResolver, host string) string {
+	cname, err := resolver.LookupCNAME(ctx, host)
+	if err != nil {
+		return ""
+	}
+	cname = strings.TrimSuffix(cname, ".")
+	if strings.TrimSuffix(host, ".") == cname {
+		return ""
+	}
+	return cname
+}
+
+func sameAddrs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameFingerprint reports whether two probes/fingerprints describe the
+// same wildcard. If either side resolved a CNAME, that's the comparison:
+// a wildcard pointing at a load balancer or CDN can rotate the backend
+// IPs every lookup while the CNAME itself stays fixed, so requiring the
+// addresses to match too would miss exactly that case. Only when neither
+// side has a CNAME does this fall back to comparing addresses.
+func sameFingerprint(a, b *wildcardFingerprint) bool {
+	if a.cname != "" || b.cname != "" {
+		return a.cname == b.cname
+	}
+	return sameAddrs(a.addrs, b.addrs)
+}
+
+// randomLabel is a var rather than a plain func so tests can substitute a
+// deterministic sequence of labels instead of random ones.
+var randomLabel = func() string {
+	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 20)
+	for i := range b {
+		b[i] = charset[rand.Intn(len(charset))]
+	}
+	return string(b)
+}
+
+// parentZone returns host's parent zone: "api.dev.example.com" ->
+// "dev.example.com".
+func parentZone(host string) string {
+	parts := strings.SplitN(host, ".", 2)
+	if len(parts) == 2 {
+		return parts[1]
+	}
+	return host
+}
@@ -0,0 +1,91 @@
+package assetfind
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// fakeResolver answers LookupHost/LookupCNAME from fixed tables keyed by
+// host, so wildcard detection can be tested without a real nameserver.
+type fakeResolver struct {
+	addrs map[string][]string
+	cname map[string]string
+}
+
+func (f fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return f.addrs[host], nil
+}
+
+func (f fakeResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if cn, ok := f.cname[host]; ok {
+		return cn, nil
+	}
+	// net.Resolver's real behavior when there's no CNAME: return host
+	// itself with a trailing dot.
+	return strings.TrimSuffix(host, ".") + ".", nil
+}
+
+// TestProbeWildcardCNAMEOnlyMatch covers a wildcard that points at a CNAME
+// whose backend rotates IPs (a load balancer or CDN): the three probes
+// resolve to different address sets but the same CNAME. Comparing
+// addresses alone would call this "no wildcard"; the CNAME must be enough
+// to still detect it.
+func TestProbeWildcardCNAMEOnlyMatch(t *testing.T) {
+	resolver := fakeResolver{
+		addrs: map[string][]string{
+			"a.example.com": {"10.0.0.1"},
+			"b.example.com": {"10.0.0.2"},
+			"c.example.com": {"10.0.0.3"},
+		},
+		cname: map[string]string{
+			"a.example.com": "edge.cdn.example.net",
+			"b.example.com": "edge.cdn.example.net",
+			"c.example.com": "edge.cdn.example.net",
+		},
+	}
+
+	orig := randomLabel
+	labels := []string{"a", "b", "c"}
+	i := 0
+	randomLabel = func() string {
+		l := labels[i]
+		i++
+		return l
+	}
+	defer func() { randomLabel = orig }()
+
+	fp := probeWildcard(context.Background(), resolver, "example.com")
+	if fp == nil {
+		t.Fatal("expected a wildcard fingerprint, got nil")
+	}
+	if fp.cname != "edge.cdn.example.net" {
+		t.Fatalf("fingerprint cname = %q, want edge.cdn.example.net", fp.cname)
+	}
+}
+
+// TestProbeWildcardNoMatch confirms probes that disagree on both address
+// and CNAME still correctly report no wildcard.
+func TestProbeWildcardNoMatch(t *testing.T) {
+	resolver := fakeResolver{
+		addrs: map[string][]string{
+			"a.example.com": {"10.0.0.1"},
+			"b.example.com": {"10.0.0.2"},
+			"c.example.com": {"10.0.0.3"},
+		},
+	}
+
+	orig := randomLabel
+	labels := []string{"a", "b", "c"}
+	i := 0
+	randomLabel = func() string {
+		l := labels[i]
+		i++
+		return l
+	}
+	defer func() { randomLabel = orig }()
+
+	if fp := probeWildcard(context.Background(), resolver, "example.com"); fp != nil {
+		t.Fatalf("expected no wildcard, got %+v", fp)
+	}
+}